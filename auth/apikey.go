@@ -0,0 +1,28 @@
+package auth
+
+import (
+	"os"
+	"strings"
+)
+
+// APIKeys maps a long-lived API key to the UserClaims it should mint a
+// short-lived token for.
+type APIKeys map[string]UserClaims
+
+// APIKeysFromEnv parses QUICKJUDGE_API_KEYS, a comma-separated list of
+// "key:userID:role:rateLimitTier" entries, for the /v1/token endpoint.
+func APIKeysFromEnv() APIKeys {
+	keys := APIKeys{}
+	raw := os.Getenv("QUICKJUDGE_API_KEYS")
+	if raw == "" {
+		return keys
+	}
+	for _, entry := range strings.Split(raw, ",") {
+		parts := strings.SplitN(strings.TrimSpace(entry), ":", 4)
+		if len(parts) != 4 {
+			continue
+		}
+		keys[parts[0]] = UserClaims{UserID: parts[1], Role: parts[2], RateLimitTier: parts[3]}
+	}
+	return keys
+}
@@ -0,0 +1,129 @@
+// Package auth validates JWT bearer tokens on incoming submissions and
+// issues short-lived tokens for long-lived API keys, so that only
+// authenticated users can reach the grader and one user can't submit on
+// another's behalf.
+package auth
+
+import (
+	"errors"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+)
+
+// UserClaims is what a validated bearer token says about the caller.
+type UserClaims struct {
+	UserID        string `json:"sub"`
+	Role          string `json:"role"`
+	RateLimitTier string `json:"rateLimitTier"`
+}
+
+// Config is the HS256 validation (and issuance) configuration, loaded from
+// the environment so the secret never lives in source.
+type Config struct {
+	Secret   []byte
+	Issuer   string
+	Audience string
+	TokenTTL time.Duration
+}
+
+// ConfigFromEnv reads QUICKJUDGE_JWT_SECRET (required), QUICKJUDGE_JWT_ISSUER
+// and QUICKJUDGE_JWT_AUDIENCE (both optional, skipped if unset).
+func ConfigFromEnv() (Config, error) {
+	secret := os.Getenv("QUICKJUDGE_JWT_SECRET")
+	if secret == "" {
+		return Config{}, errors.New("auth: QUICKJUDGE_JWT_SECRET must be set")
+	}
+	return Config{
+		Secret:   []byte(secret),
+		Issuer:   os.Getenv("QUICKJUDGE_JWT_ISSUER"),
+		Audience: os.Getenv("QUICKJUDGE_JWT_AUDIENCE"),
+		TokenTTL: 15 * time.Minute,
+	}, nil
+}
+
+const claimsContextKey = "auth.claims"
+
+// Middleware validates the request's Authorization: Bearer <token> header
+// against cfg and, on success, stores the UserClaims on the gin context for
+// ClaimsFromContext. It responds 401 for a missing/invalid/expired token.
+func Middleware(cfg Config) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		raw := c.GetHeader("Authorization")
+		tokenStr := strings.TrimPrefix(raw, "Bearer ")
+		if tokenStr == "" || tokenStr == raw {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "missing bearer token"})
+			return
+		}
+
+		claims := &jwt.MapClaims{}
+		opts := []jwt.ParserOption{jwt.WithValidMethods([]string{"HS256"})}
+		if cfg.Issuer != "" {
+			opts = append(opts, jwt.WithIssuer(cfg.Issuer))
+		}
+		if cfg.Audience != "" {
+			opts = append(opts, jwt.WithAudience(cfg.Audience))
+		}
+
+		_, err := jwt.ParseWithClaims(tokenStr, claims, func(t *jwt.Token) (interface{}, error) {
+			return cfg.Secret, nil
+		}, opts...)
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "invalid token: " + err.Error()})
+			return
+		}
+
+		sub, _ := claims.GetSubject()
+		if sub == "" {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "token missing subject"})
+			return
+		}
+
+		role, _ := (*claims)["role"].(string)
+		tier, _ := (*claims)["rateLimitTier"].(string)
+
+		c.Set(claimsContextKey, UserClaims{UserID: sub, Role: role, RateLimitTier: tier})
+		c.Next()
+	}
+}
+
+// ClaimsFromContext returns the UserClaims that Middleware stored for this
+// request, if any.
+func ClaimsFromContext(c *gin.Context) (UserClaims, bool) {
+	v, ok := c.Get(claimsContextKey)
+	if !ok {
+		return UserClaims{}, false
+	}
+	claims, ok := v.(UserClaims)
+	return claims, ok
+}
+
+// IssueToken mints a short-lived HS256 token for the given claims, for the
+// /v1/token endpoint to hand back in exchange for a long-lived API key.
+func IssueToken(cfg Config, claims UserClaims) (string, error) {
+	now := time.Now()
+	registered := jwt.MapClaims{
+		"sub":           claims.UserID,
+		"role":          claims.Role,
+		"rateLimitTier": claims.RateLimitTier,
+		"iat":           now.Unix(),
+		"exp":           now.Add(cfg.TokenTTL).Unix(),
+	}
+	if cfg.Issuer != "" {
+		registered["iss"] = cfg.Issuer
+	}
+	if cfg.Audience != "" {
+		registered["aud"] = cfg.Audience
+	}
+
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, registered)
+	signed, err := token.SignedString(cfg.Secret)
+	if err != nil {
+		return "", fmt.Errorf("auth: signing token: %w", err)
+	}
+	return signed, nil
+}
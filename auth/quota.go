@@ -0,0 +1,85 @@
+package auth
+
+import (
+	"errors"
+	"sync"
+	"time"
+)
+
+// Tier bounds how much of the grader one user can occupy at once.
+type Tier struct {
+	MaxConcurrent int
+	MaxPerMinute  int
+}
+
+// Tiers is the built-in RateLimitTier -> Tier mapping. An unrecognized or
+// empty tier falls back to "free".
+var Tiers = map[string]Tier{
+	"free":     {MaxConcurrent: 1, MaxPerMinute: 5},
+	"standard": {MaxConcurrent: 2, MaxPerMinute: 20},
+	"premium":  {MaxConcurrent: 5, MaxPerMinute: 100},
+}
+
+var (
+	// ErrConcurrencyLimit means the user already has MaxConcurrent
+	// submissions in flight.
+	ErrConcurrencyLimit = errors.New("auth: concurrency limit reached")
+	// ErrRateLimit means the user has already submitted MaxPerMinute
+	// times in the last minute.
+	ErrRateLimit = errors.New("auth: rate limit exceeded")
+)
+
+// QuotaManager enforces per-user concurrency and per-minute rate limits.
+type QuotaManager struct {
+	mu        sync.Mutex
+	inFlight  map[string]int
+	submitted map[string][]time.Time
+}
+
+// NewQuotaManager returns an empty QuotaManager.
+func NewQuotaManager() *QuotaManager {
+	return &QuotaManager{
+		inFlight:  map[string]int{},
+		submitted: map[string][]time.Time{},
+	}
+}
+
+// Acquire reserves a concurrency slot and a rate-limit submission for
+// userID under tier, returning a release func to call once the submission
+// finishes judging. It fails fast with ErrConcurrencyLimit or ErrRateLimit
+// if the user is already at their tier's limit.
+func (q *QuotaManager) Acquire(userID, tierName string) (release func(), err error) {
+	tier, ok := Tiers[tierName]
+	if !ok {
+		tier = Tiers["free"]
+	}
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if q.inFlight[userID] >= tier.MaxConcurrent {
+		return nil, ErrConcurrencyLimit
+	}
+
+	now := time.Now()
+	cutoff := now.Add(-time.Minute)
+	recent := q.submitted[userID][:0]
+	for _, t := range q.submitted[userID] {
+		if t.After(cutoff) {
+			recent = append(recent, t)
+		}
+	}
+	if len(recent) >= tier.MaxPerMinute {
+		q.submitted[userID] = recent
+		return nil, ErrRateLimit
+	}
+
+	q.submitted[userID] = append(recent, now)
+	q.inFlight[userID]++
+
+	return func() {
+		q.mu.Lock()
+		defer q.mu.Unlock()
+		q.inFlight[userID]--
+	}, nil
+}
@@ -0,0 +1,63 @@
+package auth
+
+import "testing"
+
+func TestAcquireEnforcesConcurrencyLimit(t *testing.T) {
+	q := NewQuotaManager()
+
+	release, err := q.Acquire("user1", "free")
+	if err != nil {
+		t.Fatalf("first Acquire: %v", err)
+	}
+
+	if _, err := q.Acquire("user1", "free"); err != ErrConcurrencyLimit {
+		t.Fatalf("second concurrent Acquire = %v, want ErrConcurrencyLimit", err)
+	}
+
+	release()
+
+	if _, err := q.Acquire("user1", "free"); err != nil {
+		t.Fatalf("Acquire after release: %v", err)
+	}
+}
+
+func TestAcquireEnforcesRateLimit(t *testing.T) {
+	q := NewQuotaManager()
+
+	for i := 0; i < Tiers["free"].MaxPerMinute; i++ {
+		release, err := q.Acquire("user1", "free")
+		if err != nil {
+			t.Fatalf("Acquire %d: %v", i, err)
+		}
+		release()
+	}
+
+	if _, err := q.Acquire("user1", "free"); err != ErrRateLimit {
+		t.Fatalf("Acquire past MaxPerMinute = %v, want ErrRateLimit", err)
+	}
+}
+
+func TestAcquireUnknownTierFallsBackToFree(t *testing.T) {
+	q := NewQuotaManager()
+
+	release, err := q.Acquire("user1", "nonexistent-tier")
+	if err != nil {
+		t.Fatalf("Acquire with unknown tier: %v", err)
+	}
+	defer release()
+
+	if _, err := q.Acquire("user1", "nonexistent-tier"); err != ErrConcurrencyLimit {
+		t.Fatalf("second Acquire under unknown tier = %v, want ErrConcurrencyLimit (free tier's MaxConcurrent=1)", err)
+	}
+}
+
+func TestAcquireTracksUsersIndependently(t *testing.T) {
+	q := NewQuotaManager()
+
+	if _, err := q.Acquire("user1", "free"); err != nil {
+		t.Fatalf("Acquire user1: %v", err)
+	}
+	if _, err := q.Acquire("user2", "free"); err != nil {
+		t.Fatalf("Acquire user2 should be unaffected by user1's quota: %v", err)
+	}
+}
@@ -0,0 +1,270 @@
+// Package checker implements pluggable output comparison ("special judges")
+// for the grader, replacing the old hardcoded strip-and-compare logic with
+// testlib/ICPC-style checkers.
+package checker
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/base64"
+	"fmt"
+	"github.com/BayviewComputerClub/quickjudge-go/sandbox"
+	"io/ioutil"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Verdict is what a Checker decided about a submission's output.
+type Verdict int
+
+const (
+	Accepted Verdict = iota
+	WrongAnswer
+	PresentationError
+)
+
+func (v Verdict) String() string {
+	switch v {
+	case Accepted:
+		return "AC"
+	case PresentationError:
+		return "PE"
+	default:
+		return "WA"
+	}
+}
+
+// Checker compares a submission's actual output against the expected
+// output for a given input, returning a verdict and human-readable
+// feedback explaining it.
+type Checker interface {
+	Check(input, expected, actual []byte) (Verdict, string)
+}
+
+// New builds the Checker named by a Request's Checker field:
+//
+//	""               -> token match (the grader's historical default)
+//	"diff"           -> token match
+//	"exact"          -> byte-exact match
+//	"line"           -> line-by-line, trimmed match
+//	"float:<eps>"    -> token match with floating-point tolerance
+//	"custom:<b64>"   -> base64-encoded special judge binary, jailed in box
+//
+// box is the same sandbox the submission itself runs in; a custom checker
+// binary is attacker-supplied (it ships inside the request), so it is
+// written into and executed through box exactly like the submission is,
+// never as a bare host process.
+func New(spec string, box *sandbox.Sandbox) (Checker, error) {
+	switch {
+	case spec == "" || spec == "diff":
+		return TokenChecker{}, nil
+	case spec == "exact":
+		return ExactChecker{}, nil
+	case spec == "line":
+		return LineChecker{}, nil
+	case strings.HasPrefix(spec, "float:"):
+		eps, err := strconv.ParseFloat(strings.TrimPrefix(spec, "float:"), 64)
+		if err != nil {
+			return nil, fmt.Errorf("checker: invalid float tolerance %q: %w", spec, err)
+		}
+		return FloatChecker{AbsEps: eps, RelEps: eps}, nil
+	case strings.HasPrefix(spec, "custom:"):
+		return newCustomChecker(strings.TrimPrefix(spec, "custom:"), box)
+	default:
+		return nil, fmt.Errorf("checker: unknown checker spec %q", spec)
+	}
+}
+
+// ExactChecker requires actual and expected to match byte-for-byte once all
+// trailing "\r" and "\n" bytes are stripped from each.
+type ExactChecker struct{}
+
+func (ExactChecker) Check(input, expected, actual []byte) (Verdict, string) {
+	if bytes.Equal(trimTrailingNewline(actual), trimTrailingNewline(expected)) {
+		return Accepted, ""
+	}
+	return WrongAnswer, "output differs from the expected answer"
+}
+
+func trimTrailingNewline(b []byte) []byte {
+	return bytes.TrimRight(b, "\r\n")
+}
+
+// TokenChecker splits both outputs on whitespace and compares token by
+// token, so a trailing newline or extra space never causes a WA, but a
+// missing token (and hence a different token count) always does — unlike
+// the old ReplaceAll(" ", "")-based comparison, which collapsed everything
+// down to one string and couldn't tell "1 2" from "12".
+type TokenChecker struct{}
+
+func (TokenChecker) Check(input, expected, actual []byte) (Verdict, string) {
+	a := strings.Fields(string(actual))
+	e := strings.Fields(string(expected))
+	if len(a) != len(e) {
+		return WrongAnswer, fmt.Sprintf("expected %d tokens, got %d", len(e), len(a))
+	}
+	for i := range a {
+		if a[i] != e[i] {
+			return WrongAnswer, fmt.Sprintf("token %d: expected %q, got %q", i, e[i], a[i])
+		}
+	}
+	return Accepted, ""
+}
+
+// LineChecker compares line by line after trimming surrounding whitespace
+// from each line, tolerating trailing blank lines at the end of either
+// output.
+type LineChecker struct{}
+
+func (LineChecker) Check(input, expected, actual []byte) (Verdict, string) {
+	a := trimmedLines(actual)
+	e := trimmedLines(expected)
+	if len(a) != len(e) {
+		return WrongAnswer, fmt.Sprintf("expected %d lines, got %d", len(e), len(a))
+	}
+	for i := range a {
+		if a[i] != e[i] {
+			return WrongAnswer, fmt.Sprintf("line %d: expected %q, got %q", i+1, e[i], a[i])
+		}
+	}
+	return Accepted, ""
+}
+
+func trimmedLines(b []byte) []string {
+	var lines []string
+	s := bufio.NewScanner(bytes.NewReader(b))
+	for s.Scan() {
+		lines = append(lines, strings.TrimSpace(s.Text()))
+	}
+	for len(lines) > 0 && lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return lines
+}
+
+// FloatChecker token-matches like TokenChecker, but tokens that parse as
+// floats are compared within AbsEps absolute or RelEps relative tolerance
+// instead of requiring an exact string match.
+type FloatChecker struct {
+	AbsEps float64
+	RelEps float64
+}
+
+func (f FloatChecker) Check(input, expected, actual []byte) (Verdict, string) {
+	a := strings.Fields(string(actual))
+	e := strings.Fields(string(expected))
+	if len(a) != len(e) {
+		return WrongAnswer, fmt.Sprintf("expected %d tokens, got %d", len(e), len(a))
+	}
+	for i := range a {
+		af, aErr := strconv.ParseFloat(a[i], 64)
+		ef, eErr := strconv.ParseFloat(e[i], 64)
+		if aErr != nil || eErr != nil {
+			if a[i] != e[i] {
+				return WrongAnswer, fmt.Sprintf("token %d: expected %q, got %q", i, e[i], a[i])
+			}
+			continue
+		}
+		diff := af - ef
+		if diff < 0 {
+			diff = -diff
+		}
+		rel := diff
+		if ef != 0 {
+			rel = diff / absFloat(ef)
+		}
+		if diff > f.AbsEps && rel > f.RelEps {
+			return WrongAnswer, fmt.Sprintf("token %d: expected %v, got %v (diff %v exceeds tolerance)", i, ef, af, diff)
+		}
+	}
+	return Accepted, ""
+}
+
+func absFloat(f float64) float64 {
+	if f < 0 {
+		return -f
+	}
+	return f
+}
+
+// checkerTimeout bounds how long a custom checker binary may run; it is
+// deliberately generous since checkers sometimes do real work (e.g.
+// re-simulating a submission's answer), but still short enough that a
+// misbehaving or hostile checker can't wedge a grading run forever.
+const checkerTimeout = 10 * time.Second
+
+// checkerMaxOutputBytes caps the checker's stdout/stderr; only stderr is
+// ever read back as feedback, so this just needs to be generous enough for
+// a diagnostic message.
+const checkerMaxOutputBytes = 1 << 20
+
+// checkerLimits bounds the checker binary's own run, independent of
+// whatever Limits the submission itself ran under. box.Limits is mutated
+// per-test-case by the caller (to apply that case's, possibly tiny,
+// time/memory limit to the submission), so the checker must never run
+// through it directly or it would inherit those same cramped limits.
+var checkerLimits = sandbox.Limits{
+	WallTime: checkerTimeout,
+	CPUTime:  checkerTimeout,
+	MemoryKB: 512 * 1024,
+	FileKB:   64 * 1024,
+	MaxProcs: 16,
+}
+
+// CustomChecker runs a problem-supplied special-judge binary the way
+// ICPC/testlib checkers work: it is invoked as
+// `checker <input-file> <answer-file> <output-file>` and its exit code
+// decides the verdict (0 = AC, 1 = WA, 2 = PE), with stderr used as
+// feedback. The binary is attacker-controlled (it arrives base64-encoded
+// inside the request), so it is executed through the same Sandbox as the
+// submission itself rather than as a bare host process.
+type CustomChecker struct {
+	box     *sandbox.Sandbox
+	binPath string // jail-relative path, e.g. "/checker.bin"
+}
+
+func newCustomChecker(encodedBinary string, box *sandbox.Sandbox) (Checker, error) {
+	bin, err := base64.StdEncoding.WithPadding('=').DecodeString(encodedBinary)
+	if err != nil {
+		return nil, fmt.Errorf("checker: decoding custom checker: %w", err)
+	}
+	if err := ioutil.WriteFile(box.Path("checker.bin"), bin, 0755); err != nil {
+		return nil, fmt.Errorf("checker: writing custom checker: %w", err)
+	}
+	return CustomChecker{box: box, binPath: "/checker.bin"}, nil
+}
+
+func (cc CustomChecker) Check(input, expected, actual []byte) (Verdict, string) {
+	if err := ioutil.WriteFile(cc.box.Path("checker-input.txt"), input, 0644); err != nil {
+		return WrongAnswer, err.Error()
+	}
+	if err := ioutil.WriteFile(cc.box.Path("checker-answer.txt"), expected, 0644); err != nil {
+		return WrongAnswer, err.Error()
+	}
+	if err := ioutil.WriteFile(cc.box.Path("checker-output.txt"), actual, 0644); err != nil {
+		return WrongAnswer, err.Error()
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), checkerTimeout)
+	defer cancel()
+
+	res, err := cc.box.RunWithLimits(ctx, checkerLimits, nil, checkerMaxOutputBytes, cc.binPath,
+		"/checker-input.txt", "/checker-answer.txt", "/checker-output.txt")
+	if err != nil {
+		return WrongAnswer, err.Error()
+	}
+	feedback := strings.TrimSpace(string(res.Stderr))
+
+	switch res.ExitCode {
+	case 0:
+		return Accepted, feedback
+	case 1:
+		return WrongAnswer, feedback
+	case 2:
+		return PresentationError, feedback
+	default:
+		return WrongAnswer, fmt.Sprintf("checker exited %d: %s", res.ExitCode, feedback)
+	}
+}
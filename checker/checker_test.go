@@ -0,0 +1,106 @@
+package checker
+
+import "testing"
+
+func TestExactChecker(t *testing.T) {
+	var c ExactChecker
+
+	if v, _ := c.Check(nil, []byte("42\n"), []byte("42\n")); v != Accepted {
+		t.Fatalf("exact match = %v, want Accepted", v)
+	}
+	if v, _ := c.Check(nil, []byte("42\n"), []byte("42")); v != Accepted {
+		t.Fatalf("trailing newline difference = %v, want Accepted", v)
+	}
+	if v, _ := c.Check(nil, []byte("42"), []byte("1 2")); v != WrongAnswer {
+		t.Fatalf("mismatched output = %v, want WrongAnswer", v)
+	}
+}
+
+func TestTokenChecker(t *testing.T) {
+	var c TokenChecker
+
+	if v, _ := c.Check(nil, []byte("1 2"), []byte("1  2\n")); v != Accepted {
+		t.Fatalf("whitespace-insensitive match = %v, want Accepted", v)
+	}
+	// The old ReplaceAll(" ", "")-based comparison collapsed both of these
+	// down to the same string; TokenChecker must tell them apart.
+	if v, _ := c.Check(nil, []byte("1 2"), []byte("12")); v != WrongAnswer {
+		t.Fatalf(`"1 2" vs "12" = %v, want WrongAnswer`, v)
+	}
+	if v, _ := c.Check(nil, []byte("1 2 3"), []byte("1 2")); v != WrongAnswer {
+		t.Fatalf("missing token = %v, want WrongAnswer", v)
+	}
+}
+
+func TestLineChecker(t *testing.T) {
+	var c LineChecker
+
+	expected := "foo\nbar\n"
+	actual := "  foo  \nbar\n\n"
+	if v, _ := c.Check(nil, []byte(expected), []byte(actual)); v != Accepted {
+		t.Fatalf("trimmed/blank-tolerant match = %v, want Accepted", v)
+	}
+	if v, _ := c.Check(nil, []byte("foo\nbar\n"), []byte("foo\nbaz\n")); v != WrongAnswer {
+		t.Fatalf("differing line = %v, want WrongAnswer", v)
+	}
+}
+
+func TestFloatChecker(t *testing.T) {
+	c := FloatChecker{AbsEps: 1e-6, RelEps: 1e-6}
+
+	if v, _ := c.Check(nil, []byte("1.000000"), []byte("1.0000001")); v != Accepted {
+		t.Fatalf("within tolerance = %v, want Accepted", v)
+	}
+	if v, _ := c.Check(nil, []byte("1.0"), []byte("1.1")); v != WrongAnswer {
+		t.Fatalf("outside tolerance = %v, want WrongAnswer", v)
+	}
+	// Non-numeric tokens still have to match exactly.
+	if v, _ := c.Check(nil, []byte("yes"), []byte("yes")); v != Accepted {
+		t.Fatalf("non-numeric exact match = %v, want Accepted", v)
+	}
+	if v, _ := c.Check(nil, []byte("yes"), []byte("no")); v != WrongAnswer {
+		t.Fatalf("non-numeric mismatch = %v, want WrongAnswer", v)
+	}
+}
+
+func TestFloatCheckerRelativeTolerance(t *testing.T) {
+	c := FloatChecker{AbsEps: 1e-9, RelEps: 0.01}
+
+	if v, _ := c.Check(nil, []byte("1000.0"), []byte("1005.0")); v != Accepted {
+		t.Fatalf("within 1%% relative tolerance = %v, want Accepted", v)
+	}
+	if v, _ := c.Check(nil, []byte("1000.0"), []byte("1050.0")); v != WrongAnswer {
+		t.Fatalf("outside relative tolerance = %v, want WrongAnswer", v)
+	}
+}
+
+func TestNewDispatchesOnSpec(t *testing.T) {
+	cases := []struct {
+		spec string
+		want Checker
+	}{
+		{"", TokenChecker{}},
+		{"diff", TokenChecker{}},
+		{"exact", ExactChecker{}},
+		{"line", LineChecker{}},
+	}
+	for _, tc := range cases {
+		got, err := New(tc.spec, nil)
+		if err != nil {
+			t.Fatalf("New(%q): %v", tc.spec, err)
+		}
+		if got != tc.want {
+			t.Fatalf("New(%q) = %#v, want %#v", tc.spec, got, tc.want)
+		}
+	}
+
+	if _, err := New("float:1e-6", nil); err != nil {
+		t.Fatalf("New(float:1e-6): %v", err)
+	}
+	if _, err := New("float:not-a-number", nil); err == nil {
+		t.Fatal("New(float:not-a-number) should have errored")
+	}
+	if _, err := New("nonsense", nil); err == nil {
+		t.Fatal("New(nonsense) should have errored on an unknown spec")
+	}
+}
@@ -0,0 +1,132 @@
+// Command grader is the streaming alternative to the REST grader in
+// main.go: it dials a scheduler, registers with a shared secret, and then
+// judges whatever JudgeJobs the scheduler sends it, streaming a JudgeEvent
+// back for every stage instead of waiting until the submission is done.
+package main
+
+import (
+	"context"
+	"flag"
+	"github.com/BayviewComputerClub/quickjudge-go/engine"
+	pb "github.com/BayviewComputerClub/quickjudge-go/proto"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"log"
+	"os"
+	"sync"
+)
+
+func main() {
+	schedulerAddr := flag.String("scheduler", "localhost:9090", "address of the scheduler to register with")
+	graderID := flag.String("grader-id", "grader-1", "identifier this grader registers under")
+	flag.Parse()
+
+	secret := os.Getenv("QUICKJUDGE_GRADER_SECRET")
+	if secret == "" {
+		log.Fatal("QUICKJUDGE_GRADER_SECRET must be set")
+	}
+
+	conn, err := grpc.Dial(*schedulerAddr, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		log.Fatalf("dialing scheduler: %v", err)
+	}
+	defer conn.Close()
+
+	client := pb.NewSchedulerClient(conn)
+	stream, err := client.Stream(context.Background())
+	if err != nil {
+		log.Fatalf("opening scheduler stream: %v", err)
+	}
+
+	var sendMu sync.Mutex
+	send := func(msg *pb.AgentMessage) error {
+		sendMu.Lock()
+		defer sendMu.Unlock()
+		return stream.Send(msg)
+	}
+
+	if err := send(&pb.AgentMessage{Payload: &pb.AgentMessage_Register{Register: &pb.Register{
+		SharedSecret: secret,
+		GraderId:     *graderID,
+		Languages:    []string{"c++", "java", "python"},
+	}}}); err != nil {
+		log.Fatalf("registering with scheduler: %v", err)
+	}
+
+	log.Printf("registered as %s with %s, waiting for jobs", *graderID, *schedulerAddr)
+
+	for {
+		msg, err := stream.Recv()
+		if err != nil {
+			log.Fatalf("scheduler stream closed: %v", err)
+		}
+
+		switch payload := msg.Payload.(type) {
+		case *pb.SchedulerMessage_Ack:
+			if !payload.Ack.Accepted {
+				log.Fatalf("scheduler rejected registration: %s", payload.Ack.Message)
+			}
+		case *pb.SchedulerMessage_Job:
+			go judgeJob(send, payload.Job)
+		}
+	}
+}
+
+// judgeJob runs a JudgeJob through the shared engine, forwarding every
+// Event as a JudgeEvent over send.
+func judgeJob(send func(*pb.AgentMessage) error, job *pb.JudgeJob) {
+	req := engine.Request{
+		ProblemID:       job.ProblemId,
+		UserID:          job.UserId,
+		InputCode:       job.InputCode,
+		Lang:            job.Lang,
+		StopOnFirstFail: job.StopOnFirstFail,
+		Checker:         job.Checker,
+	}
+	for _, tc := range job.TestCases {
+		req.TestCases = append(req.TestCases, engine.TestCase{
+			Input:     tc.Input,
+			Output:    tc.Output,
+			Points:    int(tc.Points),
+			Timelimit: int(tc.Timelimit),
+			Memlimit:  tc.Memlimit,
+			Subtask:   int(tc.Subtask),
+		})
+	}
+
+	engine.Judge(req, func(ev engine.Event) {
+		event := &pb.JudgeEvent{SubmissionId: job.SubmissionId}
+		switch ev.Stage {
+		case "compile_started":
+			event.Payload = &pb.JudgeEvent_CompileStarted{CompileStarted: &pb.CompileStarted{}}
+		case "compile_finished":
+			event.Payload = &pb.JudgeEvent_CompileFinished{CompileFinished: &pb.CompileFinished{
+				Success:      ev.Message == "",
+				ErrorContent: ev.Message,
+			}}
+		case "case_started":
+			event.Payload = &pb.JudgeEvent_CaseStarted{CaseStarted: &pb.CaseStarted{Index: int32(ev.Index)}}
+		case "case_finished":
+			event.Payload = &pb.JudgeEvent_CaseFinished{CaseFinished: &pb.CaseFinished{
+				Index:        int32(ev.Case.Index),
+				Verdict:      string(ev.Case.Verdict),
+				TimeMs:       ev.Case.TimeMS,
+				MemoryKb:     ev.Case.MemoryKB,
+				Points:       int32(ev.Case.Points),
+				ErrorContent: ev.Case.ErrorContent,
+			}}
+		case "final":
+			event.Payload = &pb.JudgeEvent_Final{Final: &pb.Final{
+				Accepted: ev.Final.Accepted,
+				Score:    int32(ev.Final.Score),
+				MaxScore: int32(ev.Final.MaxScore),
+			}}
+		default:
+			return
+		}
+
+		if err := send(&pb.AgentMessage{Payload: &pb.AgentMessage_Event{Event: event}}); err != nil {
+			log.Printf("submission %s: sending event: %v", job.SubmissionId, err)
+		}
+	})
+}
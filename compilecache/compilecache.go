@@ -0,0 +1,176 @@
+// Package compilecache stores compiled submission artifacts in a
+// content-addressed directory keyed by a hash of their source, so
+// resubmitting the same source against a different testset (or a
+// different problem entirely) skips recompilation. Eviction follows an
+// LRU policy bounded by a configurable total size cap, mirroring how
+// Docker BuildKit accounts for and prunes its build cache.
+package compilecache
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// Entry describes one cached artifact, as surfaced by Stats.
+type Entry struct {
+	Key      string    `json:"key"`
+	Bytes    int64     `json:"bytes"`
+	LastUsed time.Time `json:"lastUsed"`
+}
+
+// Stats is the verbose disk-usage-style view of the cache's contents.
+type Stats struct {
+	TotalBytes int64   `json:"totalBytes"`
+	EntryCount int     `json:"entryCount"`
+	HitRatio   float64 `json:"hitRatio"`
+	Entries    []Entry `json:"entries"`
+}
+
+// Cache is a content-addressed store of compiled artifacts on disk.
+type Cache struct {
+	dir      string
+	maxBytes int64
+
+	mu      sync.Mutex
+	entries map[string]*Entry
+	hits    int64
+	misses  int64
+}
+
+// New opens (creating if necessary) a cache rooted at dir, capped at
+// maxBytes of total artifact size.
+func New(dir string, maxBytes int64) (*Cache, error) {
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, err
+	}
+	c := &Cache{dir: dir, maxBytes: maxBytes, entries: map[string]*Entry{}}
+
+	infos, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	for _, fi := range infos {
+		if fi.IsDir() {
+			continue
+		}
+		c.entries[fi.Name()] = &Entry{Key: fi.Name(), Bytes: fi.Size(), LastUsed: fi.ModTime()}
+	}
+
+	return c, nil
+}
+
+// Key hashes the decoded source plus the language and compiler flags used
+// to build it, so identical submissions against different testsets (or
+// different problems) share one cached artifact.
+func Key(source []byte, lang string, flags []string) string {
+	h := sha256.New()
+	h.Write([]byte(lang))
+	h.Write([]byte{0})
+	for _, f := range flags {
+		h.Write([]byte(f))
+		h.Write([]byte{0})
+	}
+	h.Write(source)
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// Get returns the cached artifact's bytes for key, if present, bumping its
+// last-used time and the cache's hit counter.
+func (c *Cache) Get(key string) ([]byte, bool) {
+	c.mu.Lock()
+	entry, ok := c.entries[key]
+	if !ok {
+		c.misses++
+		c.mu.Unlock()
+		return nil, false
+	}
+	c.hits++
+	now := time.Now()
+	entry.LastUsed = now
+	c.mu.Unlock()
+
+	data, err := ioutil.ReadFile(filepath.Join(c.dir, key))
+	if err != nil {
+		return nil, false
+	}
+	_ = os.Chtimes(filepath.Join(c.dir, key), now, now)
+	return data, true
+}
+
+// Put stores artifact under key, evicting the least-recently-used entries
+// until the cache is back under its size cap.
+func (c *Cache) Put(key string, artifact []byte) error {
+	path := filepath.Join(c.dir, key)
+	if err := ioutil.WriteFile(path, artifact, 0700); err != nil {
+		return err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.entries[key] = &Entry{Key: key, Bytes: int64(len(artifact)), LastUsed: time.Now()}
+	return c.evictLocked()
+}
+
+// evictLocked removes the least-recently-used entries until total usage is
+// back under maxBytes. Callers must hold c.mu.
+func (c *Cache) evictLocked() error {
+	if c.maxBytes <= 0 {
+		return nil
+	}
+	for c.totalBytesLocked() > c.maxBytes {
+		var oldestKey string
+		var oldest time.Time
+		for k, e := range c.entries {
+			if oldestKey == "" || e.LastUsed.Before(oldest) {
+				oldestKey, oldest = k, e.LastUsed
+			}
+		}
+		if oldestKey == "" {
+			break
+		}
+		if err := os.Remove(filepath.Join(c.dir, oldestKey)); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		delete(c.entries, oldestKey)
+	}
+	return nil
+}
+
+func (c *Cache) totalBytesLocked() int64 {
+	var total int64
+	for _, e := range c.entries {
+		total += e.Bytes
+	}
+	return total
+}
+
+// Stats returns the cache's current size, entry count, hit ratio since
+// startup, and a per-entry last-used breakdown.
+func (c *Cache) Stats() Stats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entries := make([]Entry, 0, len(c.entries))
+	var total int64
+	for _, e := range c.entries {
+		entries = append(entries, *e)
+		total += e.Bytes
+	}
+
+	ratio := 0.0
+	if n := c.hits + c.misses; n > 0 {
+		ratio = float64(c.hits) / float64(n)
+	}
+
+	return Stats{
+		TotalBytes: total,
+		EntryCount: len(entries),
+		HitRatio:   ratio,
+		Entries:    entries,
+	}
+}
@@ -0,0 +1,116 @@
+package compilecache
+
+import (
+	"testing"
+	"time"
+)
+
+func TestKeyIsDeterministicAndDistinct(t *testing.T) {
+	source := []byte("int main() {}")
+
+	a := Key(source, "c++", []string{"g++"})
+	b := Key(source, "c++", []string{"g++"})
+	if a != b {
+		t.Fatalf("Key is not deterministic: %q != %q", a, b)
+	}
+
+	if k := Key(source, "c++", []string{"g++", "-O2"}); k == a {
+		t.Fatal("Key did not change when flags changed")
+	}
+	if k := Key(source, "java", []string{"g++"}); k == a {
+		t.Fatal("Key did not change when lang changed")
+	}
+	if k := Key([]byte("int main() { return 1; }"), "c++", []string{"g++"}); k == a {
+		t.Fatal("Key did not change when source changed")
+	}
+}
+
+func TestPutGetRoundTrip(t *testing.T) {
+	c, err := New(t.TempDir(), 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	key := Key([]byte("source"), "c++", nil)
+	if _, ok := c.Get(key); ok {
+		t.Fatal("Get reported a hit before any Put")
+	}
+
+	if err := c.Put(key, []byte("binary-artifact")); err != nil {
+		t.Fatal(err)
+	}
+
+	got, ok := c.Get(key)
+	if !ok {
+		t.Fatal("Get reported a miss after Put")
+	}
+	if string(got) != "binary-artifact" {
+		t.Fatalf("Get returned %q, want %q", got, "binary-artifact")
+	}
+
+	stats := c.Stats()
+	if stats.EntryCount != 1 {
+		t.Fatalf("EntryCount = %d, want 1", stats.EntryCount)
+	}
+	if stats.HitRatio != 0.5 {
+		t.Fatalf("HitRatio = %v, want 0.5 (1 hit, 1 miss)", stats.HitRatio)
+	}
+}
+
+func TestEvictionRemovesLeastRecentlyUsed(t *testing.T) {
+	c, err := New(t.TempDir(), 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	put := func(key string, n int) {
+		if err := c.Put(key, make([]byte, n)); err != nil {
+			t.Fatal(err)
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	put("a", 4)
+	put("b", 4)
+
+	// Touch "a" so "b" becomes the least-recently-used entry.
+	if _, ok := c.Get("a"); !ok {
+		t.Fatal("expected a cache hit for \"a\"")
+	}
+	time.Sleep(time.Millisecond)
+
+	// Pushes total usage to 12 bytes, over the 10-byte cap, so the cache
+	// must evict "b" (the LRU entry) rather than "a".
+	put("c", 4)
+
+	if _, ok := c.Get("b"); ok {
+		t.Fatal("\"b\" should have been evicted as the least-recently-used entry")
+	}
+	if _, ok := c.Get("a"); !ok {
+		t.Fatal("\"a\" should have survived eviction")
+	}
+	if _, ok := c.Get("c"); !ok {
+		t.Fatal("\"c\" should have survived eviction")
+	}
+
+	if total := c.Stats().TotalBytes; total > 10 {
+		t.Fatalf("TotalBytes = %d, want <= 10 after eviction", total)
+	}
+}
+
+func TestNoEvictionWhenMaxBytesIsZero(t *testing.T) {
+	c, err := New(t.TempDir(), 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for i := 0; i < 5; i++ {
+		if err := c.Put(Key([]byte{byte(i)}, "c++", nil), make([]byte, 1<<10)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if got := c.Stats().EntryCount; got != 5 {
+		t.Fatalf("EntryCount = %d, want 5 (maxBytes=0 disables eviction)", got)
+	}
+}
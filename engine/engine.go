@@ -0,0 +1,413 @@
+// Package engine holds the grader's core judging logic: compiling a
+// submission once and running it against each TestCase. It is shared by
+// the REST handler in main.go and the streaming gRPC grader in
+// cmd/grader, so both front ends judge submissions identically.
+package engine
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/base64"
+	"fmt"
+	"github.com/BayviewComputerClub/quickjudge-go/checker"
+	"github.com/BayviewComputerClub/quickjudge-go/compilecache"
+	"github.com/BayviewComputerClub/quickjudge-go/sandbox"
+	"io/ioutil"
+	"log"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"time"
+)
+
+// defaultCacheBytes caps the compile cache at 1GiB of artifacts unless
+// overridden with QUICKJUDGE_COMPILE_CACHE_BYTES.
+const defaultCacheBytes = 1 << 30
+
+var defaultCache = mustOpenCache()
+
+func mustOpenCache() *compilecache.Cache {
+	maxBytes := int64(defaultCacheBytes)
+	if v := os.Getenv("QUICKJUDGE_COMPILE_CACHE_BYTES"); v != "" {
+		if n, err := strconv.ParseInt(v, 10, 64); err == nil {
+			maxBytes = n
+		}
+	}
+
+	dir := filepath.Join(os.TempDir(), "quickjudge-compilecache")
+	c, err := compilecache.New(dir, maxBytes)
+	if err != nil {
+		log.Printf("compilecache: disabled, failed to open %s: %v", dir, err)
+		c, _ = compilecache.New(dir, 0)
+	}
+	return c
+}
+
+// CacheStats reports the compile cache's current size, entry count and
+// hit ratio, for the /v1/cache/stats endpoint.
+func CacheStats() compilecache.Stats {
+	return defaultCache.Stats()
+}
+
+// Verdict is the outcome of judging a single TestCase.
+type Verdict string
+
+const (
+	VerdictAC      Verdict = "AC"
+	VerdictWA      Verdict = "WA"
+	VerdictTLE     Verdict = "TLE"
+	VerdictMLE     Verdict = "MLE"
+	VerdictRE      Verdict = "RE"
+	VerdictSIG     Verdict = "SIG" // killed by a signal (SIGSEGV, SIGABRT, ...), distinct from a plain nonzero exit
+	VerdictOLE     Verdict = "OLE" // stdout exceeded the output size limit
+	VerdictSkipped Verdict = "SKIPPED"
+)
+
+// TestCase is one input/expected-output pair to run the submission's
+// compiled binary against, with its own scoring weight and resource limits.
+type TestCase struct {
+	Input     string `json:"input"`
+	Output    string `json:"output"`
+	Points    int    `json:"points"`
+	Timelimit int    `json:"timelimit"` // seconds
+	Memlimit  int64  `json:"memlimit"`  // KiB
+	// Subtask groups cases that score together: if any case in a subtask
+	// fails, every case in that subtask is zeroed, even ones that passed.
+	// Zero means the case is not part of any subtask.
+	Subtask int `json:"subtask"`
+}
+
+// CaseResult is what judging a single TestCase produced.
+type CaseResult struct {
+	Index        int     `json:"index"`
+	Verdict      Verdict `json:"verdict"`
+	TimeMS       int64   `json:"timeMS"`
+	MemoryKB     int64   `json:"memoryKB"`
+	Points       int     `json:"points"`
+	Signal       int     `json:"signal,omitempty"`
+	ErrorContent string  `json:"errorContent,omitempty"`
+}
+
+// Request describes one submission to judge.
+type Request struct {
+	ProblemID string     `json:"problemID"`
+	UserID    string     `json:"userID"`
+	InputCode string     `json:"inputCode"`
+	Lang      string     `json:"lang"`
+	TestCases []TestCase `json:"testCases"`
+	// StopOnFirstFail ends judging as soon as a case doesn't score full
+	// points; the remaining cases are reported as SKIPPED.
+	StopOnFirstFail bool `json:"stopOnFirstFail"`
+	// Checker selects the output comparator: "diff" (default), "exact",
+	// "line", "float:<eps>", or "custom:<base64 special-judge binary>".
+	Checker string `json:"checker"`
+}
+
+// Return is the final result of judging a submission.
+type Return struct {
+	Accepted       bool         `json:"accepted"`
+	IsCompileError bool         `json:"isCompileError"`
+	ErrorContent   string       `json:"errorContent"`
+	Score          int          `json:"score"`
+	MaxScore       int          `json:"maxScore"`
+	Cases          []CaseResult `json:"cases"`
+}
+
+// Event is emitted at each stage of judging a submission. The REST handler
+// ignores these (it only cares about the final Return); the gRPC grader in
+// cmd/grader forwards them as JudgeEvents so a client can show live
+// progress instead of waiting for the whole submission to finish.
+type Event struct {
+	Stage   string // "compile_started", "compile_finished", "case_started", "case_finished", "final"
+	Index   int
+	Case    *CaseResult
+	Final   *Return
+	Message string // compile error content, when Stage is compile_finished and it failed
+}
+
+// NoopEvent discards Events; pass it when the caller only wants the final
+// Return, as the REST handler does.
+func NoopEvent(Event) {}
+
+const maxOutputBytes = 16 * 1024 * 1024 // reject >16MiB of stdout as OLE
+
+// Judge compiles the submission once, runs every TestCase against the
+// single resulting binary, and returns the final Return. onEvent is called
+// at each stage so a streaming caller can forward live progress.
+func Judge(req Request, onEvent func(Event)) Return {
+	box, err := sandbox.New(sandbox.DefaultLimits(req.Lang))
+	if err != nil {
+		return Return{Accepted: false, IsCompileError: true, ErrorContent: err.Error()}
+	}
+	defer box.Close()
+
+	var runName string
+	var runArgs []string
+
+	switch req.Lang {
+	case "c++":
+
+		ns, err := base64.StdEncoding.WithPadding('=').DecodeString(req.InputCode)
+		if err != nil {
+			return Return{Accepted: false, IsCompileError: true, ErrorContent: err.Error()}
+		}
+
+		if err := ioutil.WriteFile(box.Path("main.cpp"), ns, 0644); err != nil {
+			return Return{Accepted: false, IsCompileError: true, ErrorContent: err.Error()}
+		}
+
+		// compile the program once (the toolchain itself is trusted, so
+		// this still runs on the host; only the compiled artifact is
+		// jailed, and every case below reuses this one binary). A
+		// resubmission of identical source skips g++ entirely via the
+		// compile cache.
+		cppFlags := []string{"g++"}
+		cmd := exec.Command("g++", box.Path("main.cpp"), "-o", box.Path("main"))
+		if ret, ok := compileCached(ns, "c++", cppFlags, cmd, box, "main", onEvent); !ok {
+			return ret
+		}
+		runName, runArgs = "/main", nil
+
+	case "java":
+
+		ns, err := base64.StdEncoding.WithPadding('=').DecodeString(req.InputCode)
+		if err != nil {
+			return Return{Accepted: false, IsCompileError: true, ErrorContent: err.Error()}
+		}
+
+		if err := ioutil.WriteFile(box.Path("Main.java"), ns, 0644); err != nil {
+			return Return{Accepted: false, IsCompileError: true, ErrorContent: err.Error()}
+		}
+
+		cmd := exec.Command("javac", box.Path("Main.java"))
+		// javac emits one .class file per nested/anonymous class (e.g. any
+		// submission using an anonymous Comparator), not just Main.class,
+		// so the cache has to capture and restore all of them together.
+		if ret, ok := compileCached(ns, "java", []string{"javac"}, cmd, box, "Main*.class", onEvent); !ok {
+			return ret
+		}
+		runName, runArgs = "java", []string{"-cp", "/", "Main"}
+
+	case "python":
+
+		ns, err := base64.StdEncoding.WithPadding('=').DecodeString(req.InputCode)
+		if err != nil {
+			return Return{Accepted: false, IsCompileError: true, ErrorContent: err.Error()}
+		}
+
+		if err := ioutil.WriteFile(box.Path("main.py"), ns, 0644); err != nil {
+			return Return{Accepted: false, IsCompileError: true, ErrorContent: err.Error()}
+		}
+
+		// nothing to compile; run the interpreter directly
+		runName, runArgs = "python3", []string{"/main.py"}
+
+	default:
+		return Return{Accepted: false, IsCompileError: true, ErrorContent: "unsupported language: " + req.Lang}
+	}
+
+	chk, err := checker.New(req.Checker, box)
+	if err != nil {
+		return Return{Accepted: false, IsCompileError: true, ErrorContent: err.Error()}
+	}
+
+	return judgeCases(req, box, chk, onEvent, runName, runArgs)
+}
+
+// compileCached compiles with a content-addressed cache in front of it:
+// source+lang+flags hash to a cache key, and a hit unpacks the cached
+// artifacts straight into box without ever running cmd. artifactGlob
+// matches every file the compiler produces (e.g. "main" for a single g++
+// binary, or "Main*.class" for javac's Main.class plus one .class per
+// nested/anonymous class), since a cache entry has to capture and restore
+// all of them together or a submission with nested classes would compile
+// fine on a miss and then throw NoClassDefFoundError on a later hit.
+func compileCached(source []byte, lang string, flags []string, cmd *exec.Cmd, box *sandbox.Sandbox, artifactGlob string, onEvent func(Event)) (Return, bool) {
+	onEvent(Event{Stage: "compile_started"})
+
+	key := compilecache.Key(source, lang, flags)
+	if archive, ok := defaultCache.Get(key); ok {
+		if err := unpackArtifacts(box.Dir, archive); err != nil {
+			onEvent(Event{Stage: "compile_finished", Message: err.Error()})
+			return Return{Accepted: false, IsCompileError: true, ErrorContent: err.Error()}, false
+		}
+		onEvent(Event{Stage: "compile_finished"})
+		return Return{}, true
+	}
+
+	if err := cmd.Run(); err != nil {
+		fmt.Println("Compile Error")
+		onEvent(Event{Stage: "compile_finished", Message: err.Error()})
+		return Return{Accepted: false, IsCompileError: true, ErrorContent: err.Error()}, false
+	}
+
+	if archive, err := packArtifacts(box.Dir, artifactGlob); err != nil {
+		log.Printf("compilecache: failed to pack artifacts for %s: %v", key, err)
+	} else if err := defaultCache.Put(key, archive); err != nil {
+		log.Printf("compilecache: failed to store %s: %v", key, err)
+	}
+
+	onEvent(Event{Stage: "compile_finished"})
+	return Return{}, true
+}
+
+// packArtifacts zips every file in dir matching glob into a single blob
+// suitable for compilecache.Put, so a compiler that emits more than one
+// output file (javac's nested/anonymous classes) caches as one unit.
+func packArtifacts(dir, glob string) ([]byte, error) {
+	matches, err := filepath.Glob(filepath.Join(dir, glob))
+	if err != nil {
+		return nil, err
+	}
+	if len(matches) == 0 {
+		return nil, fmt.Errorf("no files matched %q in %s", glob, dir)
+	}
+
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	for _, path := range matches {
+		data, err := ioutil.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		w, err := zw.Create(filepath.Base(path))
+		if err != nil {
+			return nil, err
+		}
+		if _, err := w.Write(data); err != nil {
+			return nil, err
+		}
+	}
+	if err := zw.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// unpackArtifacts reverses packArtifacts, writing every file in archive
+// back into dir.
+func unpackArtifacts(dir string, archive []byte) error {
+	zr, err := zip.NewReader(bytes.NewReader(archive), int64(len(archive)))
+	if err != nil {
+		return err
+	}
+	for _, f := range zr.File {
+		rc, err := f.Open()
+		if err != nil {
+			return err
+		}
+		data, err := ioutil.ReadAll(rc)
+		rc.Close()
+		if err != nil {
+			return err
+		}
+		if err := ioutil.WriteFile(filepath.Join(dir, f.Name), data, 0755); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// judgeCases runs every TestCase against the binary already compiled in
+// box, accumulating score and stopping early once StopOnFirstFail trips or
+// a failing case zeros the rest of its subtask.
+func judgeCases(req Request, box *sandbox.Sandbox, chk checker.Checker, onEvent func(Event), runName string, runArgs []string) Return {
+	results := make([]CaseResult, len(req.TestCases))
+	subtaskFailed := map[int]bool{}
+	stopped := false
+	maxScore := 0
+
+	for i, tc := range req.TestCases {
+		maxScore += tc.Points
+
+		if stopped || (tc.Subtask != 0 && subtaskFailed[tc.Subtask]) {
+			results[i] = CaseResult{Index: i, Verdict: VerdictSkipped}
+			continue
+		}
+
+		onEvent(Event{Stage: "case_started", Index: i})
+		res := judgeCase(box, chk, tc, runName, runArgs)
+		res.Index = i
+		results[i] = res
+		onEvent(Event{Stage: "case_finished", Index: i, Case: &res})
+
+		if res.Verdict != VerdictAC {
+			if tc.Subtask != 0 {
+				subtaskFailed[tc.Subtask] = true
+			}
+			if req.StopOnFirstFail {
+				stopped = true
+			}
+		}
+	}
+
+	// a failing case zeros every case in its subtask, including ones that
+	// individually scored full points
+	for i, tc := range req.TestCases {
+		if tc.Subtask != 0 && subtaskFailed[tc.Subtask] {
+			results[i].Points = 0
+		}
+	}
+
+	score := 0
+	for _, r := range results {
+		score += r.Points
+	}
+
+	ret := Return{
+		Accepted: score == maxScore && maxScore > 0,
+		Score:    score,
+		MaxScore: maxScore,
+		Cases:    results,
+	}
+	onEvent(Event{Stage: "final", Final: &ret})
+	return ret
+}
+
+// judgeCase runs the compiled submission against a single TestCase inside
+// box and compares its output, producing a CaseResult with the verdict,
+// resource usage and points earned.
+func judgeCase(box *sandbox.Sandbox, chk checker.Checker, tc TestCase, runName string, runArgs []string) CaseResult {
+	limits := box.Limits
+	if tc.Timelimit > 0 {
+		limits.WallTime = time.Duration(tc.Timelimit) * time.Second
+	}
+	if tc.Memlimit > 0 {
+		limits.MemoryKB = tc.Memlimit
+	}
+	box.Limits = limits
+
+	ctx, cancel := context.WithTimeout(context.Background(), limits.WallTime)
+	defer cancel()
+
+	res, err := box.Run(ctx, []byte(tc.Input), maxOutputBytes, runName, runArgs...)
+	if err != nil {
+		return CaseResult{Verdict: VerdictRE, ErrorContent: err.Error()}
+	}
+
+	switch {
+	case res.Killed && res.KilledReason == sandbox.KillWall:
+		return CaseResult{TimeMS: res.WallTime.Milliseconds(), MemoryKB: res.PeakRSSKB, Verdict: VerdictTLE}
+	case res.Killed && res.KilledReason == sandbox.KillCPU:
+		// CPU-time limit is reported the same as wall-time: both are a
+		// time limit exceeded from the submission's point of view.
+		return CaseResult{TimeMS: res.WallTime.Milliseconds(), MemoryKB: res.PeakRSSKB, Verdict: VerdictTLE}
+	case res.Killed && res.KilledReason == sandbox.KillMemory:
+		return CaseResult{TimeMS: res.WallTime.Milliseconds(), MemoryKB: res.PeakRSSKB, Verdict: VerdictMLE}
+	case res.Signal != 0:
+		return CaseResult{TimeMS: res.WallTime.Milliseconds(), MemoryKB: res.PeakRSSKB, Verdict: VerdictSIG, Signal: res.Signal, ErrorContent: string(res.Stderr)}
+	case res.ExitCode != 0:
+		return CaseResult{TimeMS: res.WallTime.Milliseconds(), MemoryKB: res.PeakRSSKB, Verdict: VerdictRE, ErrorContent: string(res.Stderr)}
+	case res.StdoutTruncated:
+		return CaseResult{TimeMS: res.WallTime.Milliseconds(), MemoryKB: res.PeakRSSKB, Verdict: VerdictOLE}
+	}
+
+	verdict, feedback := chk.Check([]byte(tc.Input), []byte(tc.Output), res.Stdout)
+	if verdict != checker.Accepted {
+		return CaseResult{TimeMS: res.WallTime.Milliseconds(), MemoryKB: res.PeakRSSKB, Verdict: VerdictWA, ErrorContent: feedback}
+	}
+
+	return CaseResult{TimeMS: res.WallTime.Milliseconds(), MemoryKB: res.PeakRSSKB, Verdict: VerdictAC, Points: tc.Points}
+}
@@ -0,0 +1,121 @@
+package engine
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/BayviewComputerClub/quickjudge-go/checker"
+	"github.com/BayviewComputerClub/quickjudge-go/sandbox"
+)
+
+// exactChecker is a minimal checker.Checker used so these tests exercise
+// judgeCases's own accounting rather than a specific comparison strategy.
+type exactChecker struct{}
+
+func (exactChecker) Check(input, expected, actual []byte) (checker.Verdict, string) {
+	if string(actual) == string(expected) {
+		return checker.Accepted, ""
+	}
+	return checker.WrongAnswer, "mismatch"
+}
+
+// newTestBox opens a real Sandbox running "/bin/sh -c cat" as the
+// "submission", which just echoes stdin back on stdout; this lets judgeCases
+// run through the actual sandboxed Run path without needing a compile step.
+// It skips the test rather than failing when the host can't provide the
+// jail's required privileges (bind-mounting the runtime and dropping to an
+// unprivileged uid both need CAP_SYS_ADMIN/root).
+func newTestBox(t *testing.T) *sandbox.Sandbox {
+	t.Helper()
+	box, err := sandbox.New(sandbox.Limits{
+		WallTime: 2 * time.Second,
+		CPUTime:  2 * time.Second,
+		MemoryKB: 256 * 1024,
+		FileKB:   32 * 1024,
+		MaxProcs: 16,
+	})
+	if err != nil {
+		t.Skipf("sandbox.New: %v (needs CAP_SYS_ADMIN/root; skipping)", err)
+	}
+	t.Cleanup(func() { box.Close() })
+
+	// The jail backend itself (chroot + namespace unshare + privilege drop)
+	// needs more than root inside some container runtimes; probe it with a
+	// trivial command rather than failing every test on an environment
+	// limitation unrelated to judgeCases's own accounting logic.
+	if _, err := box.Run(context.Background(), nil, 1024, "/bin/true"); err != nil {
+		t.Skipf("sandbox jail cannot exec in this environment: %v", err)
+	}
+	return box
+}
+
+func TestJudgeCasesZerosWholeSubtaskOnFailure(t *testing.T) {
+	box := newTestBox(t)
+
+	req := Request{
+		TestCases: []TestCase{
+			{Input: "same", Output: "same", Points: 5, Subtask: 1, Timelimit: 2},
+			{Input: "a", Output: "b", Points: 5, Subtask: 1, Timelimit: 2},
+		},
+	}
+
+	ret := judgeCases(req, box, exactChecker{}, NoopEvent, "/bin/sh", []string{"-c", "cat"})
+
+	if ret.Cases[0].Verdict != VerdictAC {
+		t.Fatalf("case 0 verdict = %v, want AC", ret.Cases[0].Verdict)
+	}
+	if ret.Cases[0].Points != 0 {
+		t.Fatalf("case 0 points = %d, want 0 (subtask 1 has a failing case)", ret.Cases[0].Points)
+	}
+	if ret.Cases[1].Verdict != VerdictWA {
+		t.Fatalf("case 1 verdict = %v, want WA", ret.Cases[1].Verdict)
+	}
+	if ret.Score != 0 || ret.MaxScore != 10 || ret.Accepted {
+		t.Fatalf("ret = %+v, want Score=0 MaxScore=10 Accepted=false", ret)
+	}
+}
+
+func TestJudgeCasesStopOnFirstFailSkipsRemainingCases(t *testing.T) {
+	box := newTestBox(t)
+
+	req := Request{
+		StopOnFirstFail: true,
+		TestCases: []TestCase{
+			{Input: "a", Output: "b", Points: 5, Timelimit: 2},
+			{Input: "x", Output: "x", Points: 5, Timelimit: 2},
+		},
+	}
+
+	ret := judgeCases(req, box, exactChecker{}, NoopEvent, "/bin/sh", []string{"-c", "cat"})
+
+	if ret.Cases[0].Verdict != VerdictWA {
+		t.Fatalf("case 0 verdict = %v, want WA", ret.Cases[0].Verdict)
+	}
+	if ret.Cases[1].Verdict != VerdictSkipped {
+		t.Fatalf("case 1 verdict = %v, want SKIPPED", ret.Cases[1].Verdict)
+	}
+}
+
+func TestJudgeCasesIndependentSubtasksScoreSeparately(t *testing.T) {
+	box := newTestBox(t)
+
+	req := Request{
+		TestCases: []TestCase{
+			{Input: "a", Output: "b", Points: 5, Subtask: 1, Timelimit: 2}, // fails, zeros subtask 1
+			{Input: "x", Output: "x", Points: 5, Subtask: 2, Timelimit: 2}, // passes, subtask 2 untouched
+		},
+	}
+
+	ret := judgeCases(req, box, exactChecker{}, NoopEvent, "/bin/sh", []string{"-c", "cat"})
+
+	if ret.Cases[0].Points != 0 {
+		t.Fatalf("case 0 points = %d, want 0", ret.Cases[0].Points)
+	}
+	if ret.Cases[1].Points != 5 {
+		t.Fatalf("case 1 points = %d, want 5 (independent subtask)", ret.Cases[1].Points)
+	}
+	if ret.Score != 5 || ret.MaxScore != 10 {
+		t.Fatalf("Score=%d MaxScore=%d, want 5, 10", ret.Score, ret.MaxScore)
+	}
+}
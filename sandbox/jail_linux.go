@@ -0,0 +1,192 @@
+//go:build linux
+
+package sandbox
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"syscall"
+)
+
+// sandboxUID/sandboxGID are the unprivileged nobody:nogroup ids the jailed
+// process runs as once rlimitJail drops the grader's own privileges.
+const sandboxUID, sandboxGID = 65534, 65534
+
+// hostRuntimeBinds lists the host paths a judged program's toolchain needs
+// at runtime: the dynamic linker and libc/libstdc++ for a compiled g++
+// binary, and the java/python3 interpreters themselves. Every jail backend
+// chroots or binds the sandbox directory as the process's entire view of
+// the filesystem, so without these the directory is otherwise empty and
+// nothing can exec.
+var hostRuntimeBinds = []string{"/usr", "/lib", "/lib64", "/bin", "/etc/alternatives"}
+
+// existingRuntimeBinds filters hostRuntimeBinds down to the paths that
+// actually exist on this host (e.g. /lib64 is absent on some distros).
+func existingRuntimeBinds() []string {
+	var out []string
+	for _, p := range hostRuntimeBinds {
+		if _, err := os.Stat(p); err == nil {
+			out = append(out, p)
+		}
+	}
+	return out
+}
+
+// bindRuntimeMounts read-only bind-mounts the host toolchain paths into
+// dir before any jail backend chroots or binds it, so a dynamically-linked
+// g++ output (or javac/java/python3 itself) has a libc, a dynamic linker
+// and an interpreter to run against.
+func bindRuntimeMounts(dir string) error {
+	for _, host := range existingRuntimeBinds() {
+		target := filepath.Join(dir, host)
+		if err := os.MkdirAll(target, 0755); err != nil {
+			return fmt.Errorf("sandbox: creating bind target %s: %w", target, err)
+		}
+		if err := syscall.Mount(host, target, "", syscall.MS_BIND, ""); err != nil {
+			return fmt.Errorf("sandbox: bind-mounting %s: %w", host, err)
+		}
+		if err := syscall.Mount("", target, "", syscall.MS_BIND|syscall.MS_REMOUNT|syscall.MS_RDONLY, ""); err != nil {
+			return fmt.Errorf("sandbox: remounting %s read-only: %w", host, err)
+		}
+	}
+	return nil
+}
+
+// unmountRuntimeMounts reverses bindRuntimeMounts so the ephemeral
+// directory can be removed cleanly; it logs rather than returning an error
+// since it runs during Close(), where callers have nothing to do about a
+// single stuck mount besides leaking it.
+func unmountRuntimeMounts(dir string) {
+	for _, host := range existingRuntimeBinds() {
+		target := filepath.Join(dir, host)
+		if err := syscall.Unmount(target, syscall.MNT_DETACH); err != nil {
+			log.Printf("sandbox: unmounting %s: %v", target, err)
+		}
+	}
+}
+
+func tmpfsRoot() string {
+	if fi, err := os.Stat("/dev/shm"); err == nil && fi.IsDir() {
+		return "/dev/shm"
+	}
+	return os.TempDir()
+}
+
+// selectJail picks the strongest isolation backend available on the host:
+// nsjail, then bubblewrap, then a plain chroot+prlimit fallback. Both nsjail
+// and bubblewrap give us a new network namespace for free, which is the
+// easiest reliable way to deny submissions network access.
+func selectJail() jail {
+	if bin, err := exec.LookPath("nsjail"); err == nil {
+		return &nsjailJail{bin: bin}
+	}
+	if bin, err := exec.LookPath("bwrap"); err == nil {
+		prlimit, _ := exec.LookPath("prlimit")
+		return &bubblewrapJail{bin: bin, prlimit: prlimit}
+	}
+	prlimit, _ := exec.LookPath("prlimit")
+	return &rlimitJail{prlimit: prlimit}
+}
+
+// nsjailJail shells out to nsjail, which already bundles namespace
+// isolation (net/mount/pid), rlimits and a chroot behind one binary.
+type nsjailJail struct{ bin string }
+
+func (j *nsjailJail) command(ctx context.Context, dir string, l Limits, name string, args []string) *exec.Cmd {
+	nsArgs := []string{
+		"--quiet", "--mode", "o",
+		"--chroot", dir, "--cwd", "/",
+		"--user", strconv.Itoa(sandboxUID), "--group", strconv.Itoa(sandboxGID),
+		"--rlimit_as", strconv.FormatInt(l.MemoryKB/1024, 10),
+		"--rlimit_cpu", strconv.FormatInt(int64(l.CPUTime.Seconds()), 10),
+		"--rlimit_fsize", strconv.FormatInt(l.FileKB/1024, 10),
+		"--rlimit_nproc", strconv.Itoa(l.MaxProcs),
+		"--",
+		name,
+	}
+	nsArgs = append(nsArgs, args...)
+	return exec.CommandContext(ctx, j.bin, nsArgs...)
+}
+
+// bubblewrapJail uses bwrap for the network/mount namespace and falls back
+// to prlimit(1), run inside the same bwrap sandbox, for the rlimits that
+// bwrap itself doesn't expose.
+type bubblewrapJail struct {
+	bin     string
+	prlimit string
+}
+
+func (j *bubblewrapJail) command(ctx context.Context, dir string, l Limits, name string, args []string) *exec.Cmd {
+	if j.prlimit == "" {
+		log.Printf("sandbox: prlimit(1) not found on PATH; rlimit enforcement is disabled for this run, falling back to the wall-time context and post-hoc memory/CPU checks only")
+	}
+	bwArgs := []string{
+		"--unshare-net", "--unshare-pid", "--unshare-user", "--die-with-parent",
+		"--uid", strconv.Itoa(sandboxUID), "--gid", strconv.Itoa(sandboxGID),
+		"--bind", dir, "/",
+		"--chdir", "/",
+		"--",
+	}
+	bwArgs = append(bwArgs, prlimitArgs(j.prlimit, l, name, args)...)
+	return exec.CommandContext(ctx, j.bin, bwArgs...)
+}
+
+// rlimitJail is the minimal fallback when neither nsjail nor bubblewrap is
+// installed: a plain chroot into the sandbox directory, with prlimit(1)
+// applying the resource limits to the process it execs.
+type rlimitJail struct{ prlimit string }
+
+func (j *rlimitJail) command(ctx context.Context, dir string, l Limits, name string, args []string) *exec.Cmd {
+	if j.prlimit == "" {
+		log.Printf("sandbox: prlimit(1) not found on PATH; rlimit enforcement is disabled for this run, falling back to the wall-time context and post-hoc memory/CPU checks only")
+	}
+	pArgs := prlimitArgs(j.prlimit, l, name, args)
+	cmd := exec.CommandContext(ctx, pArgs[0], pArgs[1:]...)
+	cmd.SysProcAttr = &syscall.SysProcAttr{
+		Chroot:     dir,
+		Cloneflags: syscall.CLONE_NEWNET | syscall.CLONE_NEWNS,
+		Credential: &syscall.Credential{Uid: sandboxUID, Gid: sandboxGID},
+	}
+	cmd.Dir = "/"
+	return cmd
+}
+
+// prlimitArgs wraps name/args in a prlimit(1) invocation that applies our
+// rlimits before exec'ing the target. If prlimit isn't installed, the
+// process still runs, just without the rlimit fallback layer.
+func prlimitArgs(prlimit string, l Limits, name string, args []string) []string {
+	if prlimit == "" {
+		return append([]string{name}, args...)
+	}
+	out := []string{
+		prlimit,
+		"--as=" + strconv.FormatInt(l.MemoryKB*1024, 10),
+		"--cpu=" + strconv.FormatInt(int64(l.CPUTime.Seconds()), 10),
+		"--fsize=" + strconv.FormatInt(l.FileKB*1024, 10),
+		"--nproc=" + strconv.Itoa(l.MaxProcs),
+		"--",
+		name,
+	}
+	return append(out, args...)
+}
+
+func peakRSSKB(ps *os.ProcessState) int64 {
+	ru, ok := ps.SysUsage().(*syscall.Rusage)
+	if !ok {
+		return 0
+	}
+	return int64(ru.Maxrss) // already KiB on Linux
+}
+
+func signalStatus(exitErr *exec.ExitError) (int, bool) {
+	status, ok := exitErr.Sys().(syscall.WaitStatus)
+	if !ok || !status.Signaled() {
+		return 0, false
+	}
+	return int(status.Signal()), true
+}
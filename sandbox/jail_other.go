@@ -0,0 +1,43 @@
+//go:build !linux
+
+package sandbox
+
+import (
+	"context"
+	"os"
+	"os/exec"
+)
+
+func tmpfsRoot() string {
+	return os.TempDir()
+}
+
+// selectJail has no namespace/chroot backend to offer outside Linux; the
+// grader is expected to run on Linux in production, so this just runs the
+// command directly and relies on the wall-time context for enforcement.
+func selectJail() jail {
+	return &noopJail{}
+}
+
+type noopJail struct{}
+
+func (j *noopJail) command(ctx context.Context, dir string, l Limits, name string, args []string) *exec.Cmd {
+	cmd := exec.CommandContext(ctx, name, args...)
+	cmd.Dir = dir
+	return cmd
+}
+
+func peakRSSKB(ps *os.ProcessState) int64 {
+	return 0
+}
+
+func signalStatus(exitErr *exec.ExitError) (int, bool) {
+	return 0, false
+}
+
+// bindRuntimeMounts/unmountRuntimeMounts are Linux-only (bind mounts); off
+// Linux, noopJail already runs the toolchain directly against the host
+// filesystem, so there is nothing to populate.
+func bindRuntimeMounts(dir string) error { return nil }
+
+func unmountRuntimeMounts(dir string) {}
@@ -0,0 +1,256 @@
+// Package sandbox runs untrusted submissions in an isolated, resource-limited
+// environment modeled on the Go Playground's sandboxing approach: each run
+// gets its own ephemeral working directory, a locked-down jail when one is
+// available on the host, and hard rlimits as a fallback everywhere else.
+package sandbox
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"time"
+)
+
+// KillReason records why a sandboxed process was terminated, so callers can
+// tell a wall-clock timeout apart from a CPU-time or memory limit being hit.
+type KillReason int
+
+const (
+	KillNone KillReason = iota
+	KillWall
+	KillCPU
+	KillMemory
+)
+
+func (k KillReason) String() string {
+	switch k {
+	case KillWall:
+		return "wall-time"
+	case KillCPU:
+		return "cpu-time"
+	case KillMemory:
+		return "memory"
+	default:
+		return "none"
+	}
+}
+
+// Limits bounds what a sandboxed process may consume. A zero WallTime,
+// CPUTime or MemoryKB turns off Run's own deadline or post-hoc check for
+// that resource; the jail backends pass every field to their rlimits as
+// given, so there a zero is a zero limit.
+type Limits struct {
+	WallTime time.Duration // hard deadline, enforced via the run context
+	CPUTime  time.Duration // RLIMIT_CPU
+	MemoryKB int64         // RLIMIT_AS, in KiB
+	FileKB   int64         // RLIMIT_FSIZE, in KiB
+	MaxProcs int           // RLIMIT_NPROC
+}
+
+// DefaultLimits returns the per-language defaults used when a Request does
+// not override them. Interpreted languages get more wall time to account for
+// startup/JIT overhead.
+func DefaultLimits(lang string) Limits {
+	switch lang {
+	case "java":
+		return Limits{WallTime: 10 * time.Second, CPUTime: 8 * time.Second, MemoryKB: 512 * 1024, FileKB: 64 * 1024, MaxProcs: 32}
+	case "python":
+		return Limits{WallTime: 10 * time.Second, CPUTime: 8 * time.Second, MemoryKB: 256 * 1024, FileKB: 32 * 1024, MaxProcs: 16}
+	default: // c++
+		return Limits{WallTime: 5 * time.Second, CPUTime: 5 * time.Second, MemoryKB: 256 * 1024, FileKB: 32 * 1024, MaxProcs: 16}
+	}
+}
+
+// Result is what a sandboxed run produced, including the accounting needed
+// to tell the caller whether (and why) the process was killed.
+type Result struct {
+	ExitCode        int
+	Signal          int
+	Killed          bool
+	KilledReason    KillReason
+	PeakRSSKB       int64
+	WallTime        time.Duration
+	CPUTime         time.Duration
+	Stdout          []byte
+	Stderr          []byte
+	StdoutTruncated bool // stdout exceeded the requested maxOutputBytes (OLE)
+}
+
+// jail abstracts over the isolation backend actually used for a run.
+type jail interface {
+	// command builds the exec.Cmd that will run name/args inside dir,
+	// applying network, filesystem and privilege isolation.
+	command(ctx context.Context, dir string, limits Limits, name string, args []string) *exec.Cmd
+}
+
+// Sandbox owns one ephemeral working directory and the jail used to run
+// processes inside it. Callers must call Close to remove the directory.
+type Sandbox struct {
+	Dir    string
+	Limits Limits
+	jail   jail
+}
+
+// New creates a fresh working directory (under the /dev/shm tmpfs where it
+// exists, otherwise under os.TempDir) and selects the
+// strongest isolation backend available on the host: nsjail, then
+// bubblewrap, then a plain chroot+setrlimit fallback.
+func New(limits Limits) (*Sandbox, error) {
+	dir, err := ioutil.TempDir(tmpfsRoot(), "quickjudge-")
+	if err != nil {
+		return nil, err
+	}
+	if err := os.Chmod(dir, 0700); err != nil {
+		os.RemoveAll(dir)
+		return nil, err
+	}
+
+	// Populate the otherwise-empty ephemeral directory with the host's
+	// toolchain/runtime paths (libc, the dynamic linker, java, python3,
+	// ...) before any jail backend chroots or binds it as the process's
+	// entire filesystem view.
+	if err := bindRuntimeMounts(dir); err != nil {
+		os.RemoveAll(dir)
+		return nil, err
+	}
+
+	return &Sandbox{
+		Dir:    dir,
+		Limits: limits,
+		jail:   selectJail(),
+	}, nil
+}
+
+// Close removes the sandbox's working directory and everything left in it,
+// so artifacts like a compiled ./main never leak into the next submission.
+func (s *Sandbox) Close() error {
+	unmountRuntimeMounts(s.Dir)
+	return os.RemoveAll(s.Dir)
+}
+
+// Path joins name onto the sandbox's working directory.
+func (s *Sandbox) Path(name string) string {
+	return filepath.Join(s.Dir, name)
+}
+
+// Run executes name/args inside the jail with stdin on its standard input,
+// enforcing the sandbox's own Limits, and returns a structured Result. The
+// output buffers are capped so a runaway process can't exhaust memory on the
+// grader host itself; bytes beyond the cap are silently dropped.
+func (s *Sandbox) Run(ctx context.Context, stdin []byte, maxOutputBytes int, name string, args ...string) (*Result, error) {
+	return s.RunWithLimits(ctx, s.Limits, stdin, maxOutputBytes, name, args...)
+}
+
+// RunWithLimits is Run, but against explicitly supplied limits instead of
+// s.Limits. Callers that need to run something other than the submission
+// itself inside the same jailed directory — e.g. a custom checker binary,
+// which should get its own generous, fixed budget rather than whatever
+// per-test-case limits the submission last ran under — use this instead of
+// mutating s.Limits around the call.
+func (s *Sandbox) RunWithLimits(ctx context.Context, limits Limits, stdin []byte, maxOutputBytes int, name string, args ...string) (*Result, error) {
+	wallCtx := ctx
+	var cancel context.CancelFunc
+	if limits.WallTime > 0 {
+		wallCtx, cancel = context.WithTimeout(ctx, limits.WallTime)
+		defer cancel()
+	}
+
+	cmd := s.jail.command(wallCtx, s.Dir, limits, name, args)
+
+	stdinPipe, err := cmd.StdinPipe()
+	if err != nil {
+		return nil, err
+	}
+	var stdout, stderr boundedBuffer
+	stdout.limit = maxOutputBytes
+	stderr.limit = maxOutputBytes
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	start := time.Now()
+	if err := cmd.Start(); err != nil {
+		return nil, err
+	}
+
+	writeErrCh := make(chan error, 1)
+	go func() {
+		_, werr := stdinPipe.Write(stdin)
+		writeErrCh <- stdinPipe.Close()
+		_ = werr
+	}()
+	<-writeErrCh
+
+	waitErr := cmd.Wait()
+	wall := time.Since(start)
+
+	res := &Result{
+		WallTime:        wall,
+		Stdout:          stdout.Bytes(),
+		Stderr:          stderr.Bytes(),
+		StdoutTruncated: stdout.Truncated(),
+	}
+
+	if ps := cmd.ProcessState; ps != nil {
+		res.CPUTime = ps.UserTime() + ps.SystemTime()
+		res.PeakRSSKB = peakRSSKB(ps)
+		res.ExitCode = ps.ExitCode()
+	}
+
+	switch {
+	case wallCtx.Err() == context.DeadlineExceeded:
+		res.Killed = true
+		res.KilledReason = KillWall
+	case limits.MemoryKB > 0 && res.PeakRSSKB > limits.MemoryKB:
+		res.Killed = true
+		res.KilledReason = KillMemory
+	case limits.CPUTime > 0 && res.CPUTime > limits.CPUTime:
+		res.Killed = true
+		res.KilledReason = KillCPU
+	}
+
+	if waitErr != nil {
+		var exitErr *exec.ExitError
+		if errors.As(waitErr, &exitErr) {
+			if status, ok := signalStatus(exitErr); ok {
+				res.Signal = status
+			}
+		} else if !res.Killed {
+			return res, waitErr
+		}
+	}
+
+	return res, nil
+}
+
+// boundedBuffer is an io.Writer that stops accepting bytes past limit rather
+// than growing without bound, guarding against output-limit-exceeded (OLE)
+// submissions taking down the grader host.
+type boundedBuffer struct {
+	buf       bytes.Buffer
+	limit     int
+	truncated bool
+}
+
+func (b *boundedBuffer) Write(p []byte) (int, error) {
+	if b.limit <= 0 {
+		return b.buf.Write(p)
+	}
+	remaining := b.limit - b.buf.Len()
+	if remaining <= 0 {
+		b.truncated = true
+		return len(p), nil // pretend we consumed it; caller treats this as OLE
+	}
+	if len(p) > remaining {
+		b.buf.Write(p[:remaining])
+		b.truncated = true
+		return len(p), nil
+	}
+	return b.buf.Write(p)
+}
+
+func (b *boundedBuffer) Bytes() []byte   { return b.buf.Bytes() }
+func (b *boundedBuffer) Truncated() bool { return b.truncated }
@@ -0,0 +1,117 @@
+package sandbox
+
+import (
+	"context"
+	"os/exec"
+	"testing"
+	"time"
+)
+
+// passthroughJail runs commands directly on the host with no chroot,
+// namespace or privilege-dropping applied, so tests can exercise Run's own
+// accounting logic (wall/memory/cpu kill-reason precedence) without needing
+// the elevated privileges nsjail/bwrap/rlimitJail require in production.
+type passthroughJail struct{}
+
+func (passthroughJail) command(ctx context.Context, dir string, l Limits, name string, args []string) *exec.Cmd {
+	return exec.CommandContext(ctx, name, args...)
+}
+
+func newTestSandbox(limits Limits) *Sandbox {
+	return &Sandbox{Dir: "/tmp", Limits: limits, jail: passthroughJail{}}
+}
+
+func TestRunKillWallTime(t *testing.T) {
+	s := newTestSandbox(Limits{WallTime: 50 * time.Millisecond})
+
+	res, err := s.Run(context.Background(), nil, 1<<20, "sleep", "5")
+	if err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	if !res.Killed || res.KilledReason != KillWall {
+		t.Fatalf("Killed=%v Reason=%v, want KillWall", res.Killed, res.KilledReason)
+	}
+}
+
+func TestRunKillMemoryTakesPrecedenceOverCPU(t *testing.T) {
+	// MemoryKB is set far below what even `true` reports as peak RSS, so the
+	// post-hoc memory check trips even though the process also "exceeds" an
+	// equally tiny CPUTime budget; Run's switch must check wall, then
+	// memory, then cpu, in that order.
+	s := newTestSandbox(Limits{WallTime: time.Second, MemoryKB: 1, CPUTime: time.Nanosecond})
+
+	res, err := s.Run(context.Background(), nil, 1<<20, "true")
+	if err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	if !res.Killed || res.KilledReason != KillMemory {
+		t.Fatalf("Killed=%v Reason=%v, want KillMemory", res.Killed, res.KilledReason)
+	}
+}
+
+func TestRunNotKilledWithinLimits(t *testing.T) {
+	s := newTestSandbox(Limits{WallTime: time.Second, MemoryKB: 1 << 20, CPUTime: time.Second})
+
+	res, err := s.Run(context.Background(), nil, 1<<20, "true")
+	if err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	if res.Killed {
+		t.Fatalf("Killed=%v Reason=%v, want not killed", res.Killed, res.KilledReason)
+	}
+}
+
+func TestRunWithLimitsIgnoresSandboxLimits(t *testing.T) {
+	// RunWithLimits must use the Limits it's given, not s.Limits, so a
+	// caller like a custom checker can run with its own budget regardless
+	// of whatever the Sandbox's own Limits currently holds.
+	s := newTestSandbox(Limits{WallTime: 10 * time.Millisecond})
+
+	res, err := s.RunWithLimits(context.Background(), Limits{WallTime: time.Second}, nil, 1<<20, "sleep", "0.05")
+	if err != nil {
+		t.Fatalf("RunWithLimits: %v", err)
+	}
+	if res.Killed {
+		t.Fatalf("Killed=%v Reason=%v, want not killed (s.Limits' 10ms wall time must not apply)", res.Killed, res.KilledReason)
+	}
+}
+
+func TestBoundedBufferTruncatesAtLimit(t *testing.T) {
+	var b boundedBuffer
+	b.limit = 4
+
+	n, err := b.Write([]byte("hello"))
+	if err != nil || n != 5 {
+		t.Fatalf("Write = %d, %v, want 5, nil", n, err)
+	}
+	if got := string(b.Bytes()); got != "hell" {
+		t.Fatalf("Bytes() = %q, want %q", got, "hell")
+	}
+	if !b.Truncated() {
+		t.Fatal("Truncated() = false, want true")
+	}
+
+	// Once truncated, further writes are dropped but still reported as
+	// consumed so the caller doesn't see a short-write error.
+	n, err = b.Write([]byte(" world"))
+	if err != nil || n != 6 {
+		t.Fatalf("Write after truncation = %d, %v, want 6, nil", n, err)
+	}
+	if got := string(b.Bytes()); got != "hell" {
+		t.Fatalf("Bytes() after truncation = %q, want %q", got, "hell")
+	}
+}
+
+func TestBoundedBufferNoLimitMeansUnbounded(t *testing.T) {
+	var b boundedBuffer
+
+	if _, err := b.Write([]byte("hello world")); err != nil {
+		t.Fatalf("Write: %v", err)
+	}
+	if b.Truncated() {
+		t.Fatal("Truncated() = true, want false")
+	}
+	if got := string(b.Bytes()); got != "hello world" {
+		t.Fatalf("Bytes() = %q", got)
+	}
+}